@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config содержит настройки, влияющие на поиск и (при необходимости) загрузку
+// ffmpeg/ffprobe. Значения приоритезируются так: CLI-флаги > переменные
+// окружения > файл конфигурации > значения по умолчанию.
+type Config struct {
+	FFmpegPath   string `yaml:"ffmpeg_path"`
+	FFprobePath  string `yaml:"ffprobe_path"`
+	AutoDownload bool   `yaml:"auto_download"`
+}
+
+// defaultConfigPaths перечисляет места, где ищется файл конфигурации, если
+// путь не указан явно флагом --config.
+func defaultConfigPaths() []string {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".config", "metamover", "config.yaml"))
+	}
+	paths = append(paths, "metamover.yaml")
+	return paths
+}
+
+// loadConfigFile читает YAML-файл конфигурации по указанному пути. Если путь
+// пуст, перебираются defaultConfigPaths(); отсутствие файла не считается
+// ошибкой — просто возвращается пустой Config.
+func loadConfigFile(path string) (*Config, error) {
+	candidates := []string{path}
+	if path == "" {
+		candidates = defaultConfigPaths()
+	}
+
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		data, err := os.ReadFile(candidate)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("ошибка чтения конфигурации %s: %w", candidate, err)
+		}
+
+		var cfg Config
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("ошибка разбора конфигурации %s: %w", candidate, err)
+		}
+		return &cfg, nil
+	}
+
+	return &Config{}, nil
+}
+
+// cliFlags собирает флаги командной строки, которые могут переопределить
+// значения из файла конфигурации и переменных окружения.
+type cliFlags struct {
+	configPath   string
+	ffmpegPath   string
+	ffprobePath  string
+	autoDownload bool
+	jobs         int
+	dryRun       bool
+	backend      string
+	keepLang     string
+	privacy      bool
+	fakeDate     string
+	randomDate   bool
+}
+
+// parseFlags регистрирует и разбирает флаги командной строки.
+func parseFlags() *cliFlags {
+	f := &cliFlags{}
+	flag.StringVar(&f.configPath, "config", "", "путь к файлу конфигурации (YAML)")
+	flag.StringVar(&f.ffmpegPath, "ffmpeg-path", "", "путь к бинарю ffmpeg")
+	flag.StringVar(&f.ffprobePath, "ffprobe-path", "", "путь к бинарю ffprobe")
+	flag.BoolVar(&f.autoDownload, "auto-download", false, "автоматически скачать статическую сборку ffmpeg, если она не найдена")
+	flag.IntVar(&f.jobs, "jobs", runtime.NumCPU(), "количество файлов, обрабатываемых параллельно")
+	flag.BoolVar(&f.dryRun, "dry-run", false, "только показать найденные метаданные, не удаляя их")
+	flag.StringVar(&f.backend, "backend", "exec", "бэкенд для работы с ffmpeg/ffprobe: exec (системные бинари); значение wasm (wazero) принимается, но завершает запуск ошибкой")
+	flag.StringVar(&f.keepLang, "keep-lang", "", "список языков через запятую (en,ru) — сохранить только аудио/субтитры на этих языках")
+	flag.BoolVar(&f.privacy, "privacy", false, "дополнительно переписать временные метки контейнера (creation_time) и mtime/atime файла")
+	flag.StringVar(&f.fakeDate, "fake-date", "", "значение creation_time/mtime в формате 2006-01-02, используется с --privacy (по умолчанию 2000-01-01)")
+	flag.BoolVar(&f.randomDate, "random-date", false, "использовать случайную дату вместо --fake-date для каждого файла, используется с --privacy")
+	flag.Parse()
+	return f
+}
+
+// resolveConfig объединяет файл конфигурации, переменные окружения и флаги
+// командной строки в итоговый Config. Приоритет: флаги > env > файл.
+func resolveConfig(f *cliFlags) (*Config, error) {
+	cfg, err := loadConfigFile(f.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if env := os.Getenv("METAMOVER_FFMPEG"); env != "" {
+		cfg.FFmpegPath = env
+	}
+	if env := os.Getenv("METAMOVER_FFPROBE"); env != "" {
+		cfg.FFprobePath = env
+	}
+
+	if f.ffmpegPath != "" {
+		cfg.FFmpegPath = f.ffmpegPath
+	}
+	if f.ffprobePath != "" {
+		cfg.FFprobePath = f.ffprobePath
+	}
+	if f.autoDownload {
+		cfg.AutoDownload = true
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// btbnReleaseAPI — адрес GitHub API последнего релиза статических сборок
+// ffmpeg, используемых для автоматической загрузки. Сборки покрывают
+// linux/windows amd64/arm64; для остальных платформ (в том числе macOS)
+// selectAsset возвращает ошибку и автозагрузка недоступна.
+const btbnReleaseAPI = "https://api.github.com/repos/BtbN/FFmpeg-Builds/releases/latest"
+
+// btbnAsset описывает один файл релиза на GitHub.
+type btbnAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+type btbnRelease struct {
+	Assets []btbnAsset `json:"assets"`
+}
+
+// downloadFFmpegBuild скачивает статическую сборку ffmpeg/ffprobe,
+// соответствующую текущим GOOS/GOARCH, проверяет её SHA-256 по checksum-файлу
+// релиза, распаковывает в кэш пользователя и делает бинари исполняемыми.
+// Возвращает директорию, в которой лежат распакованные бинари.
+func downloadFFmpegBuild(ctx context.Context) (string, error) {
+	cacheDir, err := cacheBinDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return "", fmt.Errorf("не удалось создать кэш-директорию %s: %w", cacheDir, err)
+	}
+
+	release, err := fetchLatestRelease(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	asset, err := selectAsset(release, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
+
+	checksumAsset, err := selectChecksumAsset(release, asset.Name)
+	if err != nil {
+		return "", err
+	}
+
+	archivePath := filepath.Join(cacheDir, asset.Name)
+	if err := downloadFile(ctx, asset.BrowserDownloadURL, archivePath); err != nil {
+		return "", err
+	}
+	defer os.Remove(archivePath)
+
+	wantSum, err := downloadChecksum(ctx, checksumAsset.BrowserDownloadURL, asset.Name)
+	if err != nil {
+		return "", err
+	}
+
+	gotSum, err := sha256File(archivePath)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(wantSum, gotSum) {
+		return "", fmt.Errorf("контрольная сумма не совпадает для %s: ожидалось %s, получено %s", asset.Name, wantSum, gotSum)
+	}
+
+	if err := extractArchive(archivePath, cacheDir); err != nil {
+		return "", err
+	}
+
+	for _, name := range []string{"ffmpeg", "ffprobe"} {
+		path := filepath.Join(cacheDir, binaryName(name))
+		if err := os.Chmod(path, 0o755); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("не удалось выставить права на исполнение для %s: %w", path, err)
+		}
+	}
+
+	return cacheDir, nil
+}
+
+// fetchLatestRelease запрашивает метаданные последнего релиза BtbN/FFmpeg-Builds.
+func fetchLatestRelease(ctx context.Context) (*btbnRelease, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, btbnReleaseAPI, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса релизов GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API вернул статус %d при запросе релизов", resp.StatusCode)
+	}
+
+	var release btbnRelease
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("ошибка разбора ответа GitHub API: %w", err)
+	}
+	return &release, nil
+}
+
+// selectAsset подбирает архив сборки под текущую платформу. BtbN публикует
+// сборки в формате "ffmpeg-master-latest-<os>64-gpl.{tar.xz,zip}".
+func selectAsset(release *btbnRelease, goos, goarch string) (*btbnAsset, error) {
+	osTag := map[string]string{
+		"linux":   "linux",
+		"windows": "win",
+	}[goos]
+	archTag := map[string]string{
+		"amd64": "64",
+		"arm64": "arm64",
+	}[goarch]
+
+	if osTag == "" || archTag == "" {
+		return nil, fmt.Errorf("автозагрузка ffmpeg не поддерживается для %s/%s", goos, goarch)
+	}
+
+	suffix := ".tar.xz"
+	if goos == "windows" {
+		suffix = ".zip"
+	}
+
+	for i := range release.Assets {
+		a := &release.Assets[i]
+		if strings.Contains(a.Name, osTag+archTag) && strings.HasSuffix(a.Name, suffix) && !strings.Contains(a.Name, ".sha256") {
+			return a, nil
+		}
+	}
+
+	return nil, fmt.Errorf("не найдена сборка ffmpeg для %s/%s среди ассетов релиза", goos, goarch)
+}
+
+// selectChecksumAsset находит файл контрольной суммы, соответствующий архиву.
+func selectChecksumAsset(release *btbnRelease, archiveName string) (*btbnAsset, error) {
+	for i := range release.Assets {
+		a := &release.Assets[i]
+		if a.Name == archiveName+".sha256" {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("не найден файл контрольной суммы для %s", archiveName)
+}
+
+// downloadFile скачивает URL в указанный путь.
+func downloadFile(ctx context.Context, url, dest string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ошибка загрузки %s: статус %d", url, resp.StatusCode)
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return fmt.Errorf("ошибка сохранения %s: %w", dest, err)
+	}
+	return nil
+}
+
+// downloadChecksum скачивает и разбирает файл вида "<sha256>  <имя файла>".
+func downloadChecksum(ctx context.Context, url, archiveName string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ошибка загрузки контрольной суммы: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("пустой файл контрольной суммы для %s", archiveName)
+	}
+	return fields[0], nil
+}
+
+// sha256File вычисляет SHA-256 файла в виде hex-строки.
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// extractArchive распаковывает tar.xz или zip архив сборки ffmpeg, забирая
+// только бинари ffmpeg/ffprobe из вложенной директории bin/ и кладя их в destDir.
+func extractArchive(archivePath, destDir string) error {
+	if strings.HasSuffix(archivePath, ".zip") {
+		return extractZip(archivePath, destDir)
+	}
+	return extractTarXz(archivePath, destDir)
+}
+
+func extractTarXz(archivePath, destDir string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	xzReader, err := xz.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("ошибка распаковки xz: %w", err)
+	}
+
+	tr := tar.NewReader(xzReader)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка чтения tar: %w", err)
+		}
+
+		base := filepath.Base(hdr.Name)
+		if base != "ffmpeg" && base != "ffprobe" {
+			continue
+		}
+		if err := writeExtractedFile(filepath.Join(destDir, base), tr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZip(archivePath, destDir string) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, file := range r.File {
+		base := filepath.Base(file.Name)
+		if base != "ffmpeg.exe" && base != "ffprobe.exe" {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeExtractedFile(filepath.Join(destDir, base), rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeExtractedFile(dest string, r io.Reader) error {
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return fmt.Errorf("ошибка записи %s: %w", dest, err)
+	}
+	return nil
+}
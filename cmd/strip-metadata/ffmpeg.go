@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// resolvedTools хранит пути к бинарям ffmpeg/ffprobe, найденным при запуске.
+type resolvedTools struct {
+	ffmpeg  string
+	ffprobe string
+}
+
+// errToolNotFound возвращается resolveToolPath, когда бинарь не найден ни в
+// одном из проверенных мест.
+var errToolNotFound = errors.New("бинарь не найден")
+
+// cacheBinDir возвращает директорию пользовательского кэша, в которую
+// складываются скачанные статические сборки ffmpeg/ffprobe.
+func cacheBinDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить домашнюю директорию: %w", err)
+	}
+	return filepath.Join(home, ".cache", "metamover", "bin"), nil
+}
+
+// resolveToolPath ищет бинарь name в следующем порядке: явный override
+// (флаг/env/конфиг), PATH, директория рядом с исполняемым файлом metamover,
+// пользовательский кэш (~/.cache/metamover/bin). Возвращает errToolNotFound,
+// если ни один вариант не найден.
+func resolveToolPath(name, override string) (string, error) {
+	if override != "" {
+		if _, err := os.Stat(override); err != nil {
+			return "", fmt.Errorf("указанный путь к %s недоступен: %w", name, err)
+		}
+		return override, nil
+	}
+
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(exe), binaryName(name))
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	if dir, err := cacheBinDir(); err == nil {
+		candidate := filepath.Join(dir, binaryName(name))
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+
+	return "", errToolNotFound
+}
+
+// checkFFmpeg находит рабочие пути к ffmpeg и ffprobe согласно cfg. Если хотя
+// бы один из них не найден и включён AutoDownload, скачивает статическую сборку с
+// BtbN/FFmpeg-Builds в пользовательский кэш.
+func checkFFmpeg(ctx context.Context, cfg *Config) (*resolvedTools, error) {
+	ffmpegPath, ffmpegErr := resolveToolPath("ffmpeg", cfg.FFmpegPath)
+	ffprobePath, ffprobeErr := resolveToolPath("ffprobe", cfg.FFprobePath)
+
+	if ffmpegErr == nil && ffprobeErr == nil {
+		return &resolvedTools{ffmpeg: ffmpegPath, ffprobe: ffprobePath}, nil
+	}
+
+	if !cfg.AutoDownload {
+		return nil, fmt.Errorf(
+			"ffmpeg/ffprobe не найдены. Укажите путь через --ffmpeg-path/--ffprobe-path, " +
+				"переменную METAMOVER_FFMPEG, файл конфигурации, либо запустите с --auto-download",
+		)
+	}
+
+	log.Println("ffmpeg/ffprobe не найдены. Скачиваю статическую сборку...")
+
+	dir, err := downloadFFmpegBuild(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка автоматической загрузки ffmpeg: %w", err)
+	}
+
+	tools := &resolvedTools{
+		ffmpeg:  filepath.Join(dir, binaryName("ffmpeg")),
+		ffprobe: filepath.Join(dir, binaryName("ffprobe")),
+	}
+
+	for _, path := range []string{tools.ffmpeg, tools.ffprobe} {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("скачанная сборка не содержит %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	log.Printf("ffmpeg/ffprobe готовы к использованию: %s", dir)
+	return tools, nil
+}
+
+// binaryName добавляет расширение .exe на Windows.
+func binaryName(name string) string {
+	if os.PathSeparator == '\\' {
+		return name + ".exe"
+	}
+	return name
+}
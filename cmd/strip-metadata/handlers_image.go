@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+)
+
+// imageHandler — встроенный FileHandler для растровых изображений. В отличие
+// от видео, тут не нужен ffmpeg: JPEG/PNG достаточно просто перечитать и
+// выкинуть известные маркеры/чанки с метаданными, оставив пиксельные данные
+// без перекодирования (аналогично тому, как exiftool -all= работает с этими
+// форматами).
+type imageHandler struct{}
+
+func init() {
+	RegisterHandler(imageHandler{})
+}
+
+func (imageHandler) Extensions() []string {
+	return []string{".jpg", ".jpeg", ".png", ".heic", ".heif"}
+}
+
+func (imageHandler) Probe(path string) (Metadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	switch {
+	case isJPEG(data):
+		return probeJPEG(data)
+	case isPNG(data):
+		return probePNG(data)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый или неопознанный формат изображения (HEIC/HEIF пока только определяются, но не чистятся)")
+	}
+}
+
+func (imageHandler) Strip(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	var cleaned []byte
+	switch {
+	case isJPEG(data):
+		cleaned, err = stripJPEG(data)
+	case isPNG(data):
+		cleaned, err = stripPNG(data)
+	default:
+		return fmt.Errorf("HEIC/HEIF: очистка метаданных пока не реализована")
+	}
+	if err != nil {
+		return fmt.Errorf("файл не тронут: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения прав файла: %w", err)
+	}
+
+	return os.WriteFile(path, cleaned, info.Mode())
+}
+
+func isJPEG(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0xFF && data[1] == 0xD8
+}
+
+func isPNG(data []byte) bool {
+	sig := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+	return len(data) >= len(sig) && bytes.Equal(data[:len(sig)], sig)
+}
+
+// jpegMetadataMarkers — маркеры JPEG-сегментов, которые несут метаданные, а
+// не данные изображения: APP1 (EXIF/XMP), APP13 (Photoshop IPTC), COM
+// (комментарий). APP0 (JFIF) сохраняется — он нужен некоторым декодерам.
+var jpegMetadataMarkers = map[byte]bool{
+	0xE1: true, // APP1: EXIF, XMP
+	0xED: true, // APP13: Photoshop/IPTC
+	0xFE: true, // COM
+}
+
+// probeJPEG перечисляет найденные в файле сегменты метаданных, не удаляя их.
+func probeJPEG(data []byte) (Metadata, error) {
+	meta := Metadata{}
+	_, err := walkJPEGSegments(data[2:], func(marker byte, payload []byte) {
+		if !jpegMetadataMarkers[marker] {
+			return
+		}
+		meta[fmt.Sprintf("jpeg.segment.0x%X", marker)] = fmt.Sprintf("%d bytes", len(payload))
+	})
+	return meta, err
+}
+
+// stripJPEG пересобирает файл, выкидывая сегменты из jpegMetadataMarkers и
+// сохраняя всё остальное (SOI, APP0, DQT, SOF, DHT) в исходном порядке. После
+// SOS (Start of Scan) дальше идут энтропийно-кодированные данные, а не
+// маркеры — они копируются как есть, вплоть до EOI. Если walkJPEGSegments
+// прерывается с ошибкой (обрезанный/повреждённый файл), возвращаем её и не
+// отдаём вызывающей стороне частично собранный буфер — иначе Strip перепишет
+// оригинал обрубленным файлом.
+func stripJPEG(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	out.Write(data[:2]) // SOI
+
+	scanStart, err := walkJPEGSegments(data[2:], func(marker byte, payload []byte) {
+		if jpegMetadataMarkers[marker] {
+			return
+		}
+		out.WriteByte(0xFF)
+		out.WriteByte(marker)
+		if payload != nil {
+			var length [2]byte
+			binary.BigEndian.PutUint16(length[:], uint16(len(payload)+2))
+			out.Write(length[:])
+			out.Write(payload)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if scanStart >= 0 {
+		out.Write(data[2+scanStart:])
+	}
+
+	return out.Bytes(), nil
+}
+
+// walkJPEGSegments обходит маркерные сегменты JPEG, вызывая fn для каждого
+// (marker, payload). Как только встречается SOS (Start of Scan), обход
+// останавливается и возвращает смещение (относительно data) начала
+// энтропийно-кодированных данных, которые нельзя разбирать как маркеры —
+// вызывающая сторона копирует их как есть.
+func walkJPEGSegments(data []byte, fn func(marker byte, payload []byte)) (scanStart int, err error) {
+	i := 0
+	for i < len(data) {
+		if data[i] != 0xFF {
+			return -1, fmt.Errorf("повреждённый JPEG: ожидался маркер на смещении %d", i)
+		}
+		if i+1 >= len(data) {
+			return -1, fmt.Errorf("повреждённый JPEG: обрезанный маркер на смещении %d", i)
+		}
+		marker := data[i+1]
+		i += 2
+
+		// Маркеры без полезной нагрузки (restart-маркеры, EOI).
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD9) {
+			fn(marker, nil)
+			if marker == 0xD9 {
+				return -1, nil
+			}
+			continue
+		}
+
+		if i+2 > len(data) {
+			return -1, fmt.Errorf("повреждённый JPEG: обрезанный сегмент на смещении %d", i)
+		}
+		length := int(binary.BigEndian.Uint16(data[i : i+2]))
+		if length < 2 || i+length > len(data) {
+			return -1, fmt.Errorf("повреждённый JPEG: некорректная длина сегмента на смещении %d", i)
+		}
+		payload := data[i+2 : i+length]
+		fn(marker, payload)
+		i += length
+
+		if marker == 0xDA { // SOS: дальше идут сжатые данные, а не маркеры
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// pngMetadataChunks — чанки PNG, несущие метаданные, а не изображение:
+// текстовые (tEXt/zTXt/iTXt), время последнего изменения (tIME) и eXIf.
+var pngMetadataChunks = map[string]bool{
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+	"eXIf": true,
+}
+
+func probePNG(data []byte) (Metadata, error) {
+	meta := Metadata{}
+	err := walkPNGChunks(data[8:], func(typ string, payload []byte) {
+		if pngMetadataChunks[typ] {
+			meta["png.chunk."+typ] = fmt.Sprintf("%d bytes", len(payload))
+		}
+	})
+	return meta, err
+}
+
+// stripPNG пересобирает файл, выкидывая чанки из pngMetadataChunks. Если
+// walkPNGChunks прерывается с ошибкой (обрезанный/повреждённый файл),
+// возвращаем её и не отдаём частично собранный буфер — иначе Strip перепишет
+// оригинал обрубленным файлом.
+func stripPNG(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	sig := data[:8]
+	out.Write(sig)
+
+	err := walkPNGChunks(data[8:], func(typ string, payload []byte) {
+		if pngMetadataChunks[typ] {
+			return
+		}
+		writePNGChunk(&out, typ, payload)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+// walkPNGChunks обходит все чанки PNG после 8-байтной сигнатуры, вызывая fn
+// для каждого (type, payload данные без длины/CRC).
+func walkPNGChunks(data []byte, fn func(typ string, payload []byte)) error {
+	i := 0
+	for i < len(data) {
+		if i+8 > len(data) {
+			return fmt.Errorf("повреждённый PNG: обрезанный заголовок чанка на смещении %d", i)
+		}
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		typ := string(data[i+4 : i+8])
+		start := i + 8
+		if start+length+4 > len(data) {
+			return fmt.Errorf("повреждённый PNG: некорректная длина чанка %s на смещении %d", typ, i)
+		}
+		payload := data[start : start+length]
+		fn(typ, payload)
+		i = start + length + 4 // + CRC
+		if typ == "IEND" {
+			break
+		}
+	}
+	return nil
+}
+
+// writePNGChunk сериализует чанк (длина + тип + данные + CRC32) в формате,
+// который ожидает декодер PNG.
+func writePNGChunk(out *bytes.Buffer, typ string, payload []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(payload)))
+	out.Write(length[:])
+
+	body := append([]byte(typ), payload...)
+	out.Write(body)
+
+	var crc [4]byte
+	binary.BigEndian.PutUint32(crc[:], crc32.ChecksumIEEE(body))
+	out.Write(crc[:])
+}
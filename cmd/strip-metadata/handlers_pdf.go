@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// pdfHandler — встроенный FileHandler для PDF. Полноценный PDF-writer сюда
+// не тащим: вместо перестроения объектов метаданные затираются на месте —
+// значения в словаре /Info и блок XMP (<?xpacket .. ?>) заменяются пробелами
+// той же длины. Это сохраняет все смещения xref валидными, поэтому файл
+// остаётся корректным PDF без перерасчёта таблицы смещений.
+type pdfHandler struct{}
+
+func init() {
+	RegisterHandler(pdfHandler{})
+}
+
+func (pdfHandler) Extensions() []string {
+	return []string{".pdf"}
+}
+
+// infoEntryRe ищет записи вида /Title (...) или /Author (...) внутри
+// объектов словаря /Info — это покрывает подавляющее большинство PDF,
+// записанных обычными библиотеками (строки в круглых скобках, не hex).
+var infoEntryRe = regexp.MustCompile(`/(Title|Author|Subject|Keywords|Creator|Producer|CreationDate|ModDate)\s*\(([^)\\]*(?:\\.[^)\\]*)*)\)`)
+
+// xmpPacketRe вырезает весь XMP-пакет целиком, включая теги <?xpacket ... ?>.
+var xmpPacketRe = regexp.MustCompile(`(?s)<\?xpacket begin=.*?<\?xpacket end="w"\?>`)
+
+func (pdfHandler) Probe(path string) (Metadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	meta := Metadata{}
+	for _, m := range infoEntryRe.FindAllSubmatch(data, -1) {
+		key, value := string(m[1]), string(m[2])
+		// Значения, затёртые Strip пробелами, метаданными уже не считаются.
+		if strings.TrimSpace(value) != "" {
+			meta["pdf.info."+key] = value
+		}
+	}
+	if loc := xmpPacketRe.FindIndex(data); loc != nil {
+		meta["pdf.xmp"] = fmt.Sprintf("%d bytes", loc[1]-loc[0])
+	}
+	return meta, nil
+}
+
+func (pdfHandler) Strip(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	cleaned := append([]byte(nil), data...)
+	for _, loc := range infoEntryRe.FindAllSubmatchIndex(data, -1) {
+		// loc[4:6] — границы группы 2 (значения) внутри исходного совпадения;
+		// переписываем только эти байты пробелами, не трогая ключ и
+		// разделители, иначе длина совпадения может измениться и сдвинуть
+		// все последующие смещения xref.
+		valueStart, valueEnd := loc[4], loc[5]
+		for i := valueStart; i < valueEnd; i++ {
+			cleaned[i] = ' '
+		}
+	}
+
+	cleaned = xmpPacketRe.ReplaceAllFunc(cleaned, func(match []byte) []byte {
+		return bytes.Repeat([]byte{' '}, len(match))
+	})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения прав файла: %w", err)
+	}
+
+	return os.WriteFile(path, cleaned, info.Mode())
+}
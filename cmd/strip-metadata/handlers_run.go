@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findHandledFiles находит файлы, для расширения которых в globalRegistry
+// зарегистрирован FileHandler (встроенный image/pdf или сторонний плагин), и
+// которые не покрываются videoExtensions — те идут через более быстрый
+// Scrubber/пул воркеров в findVideoFiles/runBatch.
+func findHandledFiles(dir string, skip map[string]bool) ([]string, error) {
+	var files []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		ext := strings.ToLower(filepath.Ext(path))
+		if skip[ext] {
+			return nil
+		}
+		if _, ok := HandlerFor(ext); ok {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+// runHandledFiles прогоняет файлы, не относящиеся к видео, через их
+// зарегистрированный FileHandler. В отличие от runBatch, здесь нет бounded
+// worker pool — эти обработчики (изображения, PDF, плагины) на порядок
+// быстрее ffmpeg и пока не нуждаются в той же степени параллелизма.
+func runHandledFiles(ctx context.Context, files []string, dryRun bool) batchSummary {
+	var summary batchSummary
+
+	for i, file := range files {
+		header := fmt.Sprintf("[%d/%d] %s", i+1, len(files), filepath.Base(file))
+		report, status, saved := processHandledFile(ctx, file, dryRun)
+		log.Println(strings.Join(append([]string{header}, report...), "\n"))
+
+		switch status {
+		case statusProcessed:
+			summary.processed++
+			summary.bytesSaved += saved
+		case statusSkipped:
+			summary.skipped++
+		case statusFailed:
+			summary.failed++
+		}
+	}
+
+	return summary
+}
+
+// processHandledFile обрабатывает один файл через его FileHandler: Probe,
+// затем (если не dry-run) Strip и повторный Probe, прогнанный через
+// runVerifyRules — тем же путём, каким видео-пайплайн зовёт
+// describeMetadata/verifyMetadataRemoved.
+func processHandledFile(ctx context.Context, file string, dryRun bool) (report []string, status fileStatus, bytesSaved int64) {
+	ext := strings.ToLower(filepath.Ext(file))
+	handler, ok := HandlerFor(ext)
+	if !ok {
+		return []string{"  Пропущено: нет обработчика для " + ext}, statusSkipped, 0
+	}
+
+	metadata, err := handler.Probe(file)
+	if err != nil {
+		// Файл, который не получилось даже прочитать обработчиком, не трогаем
+		// Strip'ом — для повреждённой/незнакомой структуры у обработчика нет
+		// надёжного способа понять, что можно переписать, не потеряв данные.
+		report = append(report, fmt.Sprintf("  Ошибка: не удалось прочитать метаданные: %v", err))
+		return report, statusFailed, 0
+	} else if len(metadata) == 0 {
+		report = append(report, "  Метаданные не обнаружены")
+	} else {
+		report = append(report, "  Обнаружены метаданные:")
+		for key, value := range metadata {
+			report = append(report, fmt.Sprintf("    - %s: %s", key, value))
+		}
+	}
+
+	if dryRun {
+		return report, statusSkipped, 0
+	}
+
+	sizeBefore, _ := fileSize(file)
+
+	if err := handler.Strip(ctx, file); err != nil {
+		report = append(report, fmt.Sprintf("  Ошибка обработки: %v", err))
+		return report, statusFailed, 0
+	}
+
+	remaining, err := handler.Probe(file)
+	if err != nil {
+		report = append(report, fmt.Sprintf("  Предупреждение: не удалось проверить удаление метаданных: %v", err))
+	} else {
+		report = append(report, runVerifyRules(remaining)...)
+	}
+
+	sizeAfter, _ := fileSize(file)
+	saved := sizeBefore - sizeAfter
+	if saved < 0 {
+		saved = 0
+	}
+
+	return report, statusProcessed, saved
+}
@@ -9,7 +9,6 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"time"
 )
 
 const (
@@ -18,17 +17,35 @@ const (
 )
 
 func main() {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-	defer cancel()
+	// Глобальный таймаут больше не ограничивает всю партию целиком — каждый
+	// файл получает собственный таймаут (см. perFileTimeout в pool.go), чтобы
+	// один зависший ffmpeg не останавливал обработку остальных файлов.
+	ctx := context.Background()
+
+	flags := parseFlags()
+	cfg, err := resolveConfig(flags)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
+	}
 
 	workDir, err := os.Getwd()
 	if err != nil {
 		log.Fatalf("Не удалось получить текущую директорию: %v", err)
 	}
 
-	// Проверяем наличие ffmpeg (с автоматической установкой)
-	if err := checkFFmpeg(ctx); err != nil {
-		log.Fatalf("Ошибка проверки ffmpeg: %v", err)
+	keepLang := parseKeepLang(flags.keepLang)
+	privacy, err := resolvePrivacyOptions(flags)
+	if err != nil {
+		log.Fatalf("Ошибка разбора параметров --privacy: %v", err)
+	}
+
+	scrubber, err := buildScrubber(ctx, flags.backend, cfg, keepLang, privacy)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации бэкенда %q: %v", flags.backend, err)
+	}
+
+	if err := loadPlugins(); err != nil {
+		log.Printf("Предупреждение: не удалось загрузить плагины: %v", err)
 	}
 
 	log.Printf("Сканирование директории: %s", workDir)
@@ -38,88 +55,46 @@ func main() {
 		log.Fatalf("Ошибка поиска видеофайлов: %v", err)
 	}
 
-	if len(videoFiles) == 0 {
-		log.Println("Видеофайлы не найдены")
-		return
+	handledFiles, err := findHandledFiles(workDir, videoExtSet())
+	if err != nil {
+		log.Fatalf("Ошибка поиска файлов для зарегистрированных обработчиков: %v", err)
 	}
 
-	log.Printf("Найдено видеофайлов: %d", len(videoFiles))
-
-	for i, file := range videoFiles {
-		log.Printf("\n[%d/%d] Обработка: %s", i+1, len(videoFiles), filepath.Base(file))
-
-		// Читаем метаданные до обработки
-		metadata, err := getMetadata(file)
-		if err != nil {
-			log.Printf("Предупреждение: не удалось прочитать метаданные: %v", err)
-		} else {
-			displayMetadata(metadata)
-		}
-
-		// Удаляем метаданные
-		if err := stripMetadata(ctx, file); err != nil {
-			log.Printf("Ошибка обработки %s: %v", file, err)
-			continue
-		}
-
-		// Проверяем что метаданные удалены
-		if err := verifyMetadataRemoved(file); err != nil {
-			log.Printf("Предупреждение: не удалось проверить удаление метаданных: %v", err)
-		}
-
-		log.Printf("[%d/%d] Готово: %s", i+1, len(videoFiles), filepath.Base(file))
+	if len(videoFiles) == 0 && len(handledFiles) == 0 {
+		log.Println("Подходящие файлы не найдены")
+		return
 	}
 
-	log.Println("Обработка завершена")
-}
-
-// checkFFmpeg проверяет наличие ffmpeg в системе и при необходимости устанавливает его
-func checkFFmpeg(ctx context.Context) error {
-	// Проверяем наличие ffmpeg
-	cmd := exec.Command("ffmpeg", "-version")
-	if err := cmd.Run(); err == nil {
-		return nil
+	if flags.dryRun {
+		log.Println("Режим dry-run: файлы не будут изменены")
 	}
 
-	log.Println("ffmpeg не найден. Попытка автоматической установки...")
-
-	// Проверяем наличие Homebrew
-	brewCmd := exec.Command("brew", "--version")
-	if err := brewCmd.Run(); err != nil {
-		return fmt.Errorf(
-			"ffmpeg не найден и Homebrew недоступен.\n" +
-				"Установите Homebrew: /bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)\"\n" +
-				"Затем установите ffmpeg: brew install ffmpeg",
-		)
+	var summary batchSummary
+	if len(videoFiles) > 0 {
+		log.Printf("Найдено видеофайлов: %d (воркеров: %d)", len(videoFiles), flags.jobs)
+		summary = runBatch(ctx, scrubber, videoFiles, flags.jobs, flags.dryRun, keepLang)
 	}
 
-	log.Println("Найден Homebrew. Устанавливаю ffmpeg...")
-	log.Println("Это может занять несколько минут...")
-
-	// Устанавливаем ffmpeg через brew с контекстом (таймаут 20 минут для установки)
-	installCtx, installCancel := context.WithTimeout(ctx, 20*time.Minute)
-	defer installCancel()
-
-	installCmd := exec.CommandContext(installCtx, "brew", "install", "ffmpeg")
-	installCmd.Stdout = os.Stdout
-	installCmd.Stderr = os.Stderr
-
-	if err := installCmd.Run(); err != nil {
-		if installCtx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("установка ffmpeg превысила таймаут (20 минут). Попробуйте установить вручную: brew install ffmpeg")
-		}
-		return fmt.Errorf("ошибка установки ffmpeg через brew: %w\nПопробуйте установить вручную: brew install ffmpeg", err)
+	if len(handledFiles) > 0 {
+		log.Printf("Найдено файлов для плагинов/встроенных обработчиков: %d", len(handledFiles))
+		summary.merge(runHandledFiles(ctx, handledFiles, flags.dryRun))
 	}
 
-	log.Println("ffmpeg успешно установлен!")
+	log.Printf(
+		"Обработка завершена: обработано %d, пропущено %d, ошибок %d, освобождено %s",
+		summary.processed, summary.skipped, summary.failed, formatBytes(summary.bytesSaved),
+	)
+}
 
-	// Проверяем установку еще раз
-	verifyCmd := exec.Command("ffmpeg", "-version")
-	if err := verifyCmd.Run(); err != nil {
-		return fmt.Errorf("ffmpeg установлен, но недоступен в PATH. Перезапустите терминал или выполните: export PATH=\"/opt/homebrew/bin:$PATH\"")
+// videoExtSet возвращает videoExtensions в виде множества, чтобы
+// findHandledFiles не перехватывал файлы, которые и так идут через быстрый
+// видео-пайплайн (Scrubber/пул воркеров в pool.go).
+func videoExtSet() map[string]bool {
+	set := make(map[string]bool)
+	for _, ext := range strings.Split(videoExtensions, ",") {
+		set[strings.ToLower(ext)] = true
 	}
-
-	return nil
+	return set
 }
 
 // findVideoFiles находит все видеофайлы в указанной директории
@@ -157,18 +132,35 @@ type metadataInfo struct {
 	Format struct {
 		Tags map[string]string `json:"tags"`
 	} `json:"format"`
-	Streams []struct {
-		Tags map[string]string `json:"tags"`
-	} `json:"streams"`
+	Streams  []streamInfo  `json:"streams"`
+	Chapters []chapterInfo `json:"chapters"`
+}
+
+// streamInfo описывает один поток контейнера — видео, аудио, субтитры или
+// вложение (например, шрифт). Index и CodecType/CodecName нужны, чтобы
+// tracks.go мог построить план -map и извлечь субтитры в сайдкары.
+type streamInfo struct {
+	Index     int               `json:"index"`
+	CodecType string            `json:"codec_type"`
+	CodecName string            `json:"codec_name"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// chapterInfo описывает одну главу контейнера; stripMetadata убирает все
+// главы через -map_chapters -1 (см. tracks.go:describeRemovedTracks).
+type chapterInfo struct {
+	ID   int               `json:"id"`
+	Tags map[string]string `json:"tags"`
 }
 
 // getMetadata получает метаданные из видеофайла используя ffprobe
-func getMetadata(filePath string) (*metadataInfo, error) {
-	cmd := exec.Command("ffprobe",
+func getMetadata(ffprobePath, filePath string) (*metadataInfo, error) {
+	cmd := exec.Command(ffprobePath,
 		"-v", "quiet",
 		"-print_format", "json",
 		"-show_format",
 		"-show_streams",
+		"-show_chapters",
 		filePath,
 	)
 
@@ -185,10 +177,13 @@ func getMetadata(filePath string) (*metadataInfo, error) {
 	return &info, nil
 }
 
-// displayMetadata выводит найденные метаданные
-func displayMetadata(metadata *metadataInfo) {
+// describeMetadata формирует список строк с найденными метаданными для
+// последующего вывода в лог. Вынесено из displayMetadata в отдельную функцию,
+// чтобы воркеры могли собрать вывод и передать его логирующей горутине одним
+// куском, не перемежая его с выводом других файлов.
+func describeMetadata(metadata *metadataInfo) []string {
 	if metadata == nil {
-		return
+		return nil
 	}
 
 	var foundMetadata []string
@@ -251,24 +246,34 @@ func displayMetadata(metadata *metadataInfo) {
 		}
 	}
 
-	if len(foundMetadata) > 0 {
-		log.Println("  Обнаружены метаданные:")
-		for _, meta := range foundMetadata {
-			log.Printf("    - %s", meta)
-		}
-		log.Println("  Удаляю метаданные...")
-	} else {
-		log.Println("  Метаданные не обнаружены")
+	if len(foundMetadata) == 0 {
+		return []string{"  Метаданные не обнаружены"}
+	}
+
+	lines := []string{"  Обнаружены метаданные:"}
+	for _, meta := range foundMetadata {
+		lines = append(lines, fmt.Sprintf("    - %s", meta))
 	}
+	return lines
 }
 
-// verifyMetadataRemoved проверяет что метаданные удалены после обработки
-func verifyMetadataRemoved(filePath string) error {
-	metadata, err := getMetadata(filePath)
+// verifyMetadataRemoved проверяет что метаданные удалены после обработки и
+// возвращает отчёт в виде строк лога (см. describeMetadata). suppressCreationTime
+// подавляет creation_time в отчёте об оставшихся метаданных — в режиме
+// --privacy creation_time намеренно переписан на фиктивную дату и уже
+// отдельно проверяется describeTimestampAudit, так что здесь он не "утечка".
+func verifyMetadataRemoved(ffprobePath, filePath string, suppressCreationTime bool) ([]string, error) {
+	metadata, err := getMetadata(ffprobePath, filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return describeRemaining(metadata, suppressCreationTime), nil
+}
 
+// describeRemaining формирует отчёт о метаданных, оставшихся после Strip.
+// Вынесена отдельно от verifyMetadataRemoved, чтобы ей мог пользоваться любой
+// Scrubber-бэкенд, а не только execScrubber.
+func describeRemaining(metadata *metadataInfo, suppressCreationTime bool) []string {
 	var remainingMetadata []string
 
 	// Проверяем метаданные контейнера
@@ -286,6 +291,9 @@ func verifyMetadataRemoved(filePath string) error {
 				if key == "encoder" && strings.HasPrefix(value, "Lavf") {
 					continue
 				}
+				if key == "creation_time" && suppressCreationTime {
+					continue
+				}
 				remainingMetadata = append(remainingMetadata, fmt.Sprintf("%s: %s", key, value))
 			}
 		}
@@ -294,7 +302,7 @@ func verifyMetadataRemoved(filePath string) error {
 	// Проверяем метаданные потоков (только критичные)
 	for i, stream := range metadata.Streams {
 		if stream.Tags != nil {
-			if creationTime, ok := stream.Tags["creation_time"]; ok && creationTime != "" {
+			if creationTime, ok := stream.Tags["creation_time"]; ok && creationTime != "" && !suppressCreationTime {
 				remainingMetadata = append(remainingMetadata, fmt.Sprintf("stream[%d].creation_time: %s", i, creationTime))
 			}
 			if encoder, ok := stream.Tags["encoder"]; ok && encoder != "" && !strings.HasPrefix(encoder, "Lav") {
@@ -306,20 +314,22 @@ func verifyMetadataRemoved(filePath string) error {
 		}
 	}
 
-	if len(remainingMetadata) > 0 {
-		log.Println("  ⚠️  Предупреждение: остались метаданные:")
-		for _, meta := range remainingMetadata {
-			log.Printf("    - %s", meta)
-		}
-	} else {
-		log.Println("  ✓ Метаданные успешно удалены")
+	if len(remainingMetadata) == 0 {
+		return []string{"  ✓ Метаданные успешно удалены"}
 	}
 
-	return nil
+	lines := []string{"  ⚠️  Предупреждение: остались метаданные:"}
+	for _, meta := range remainingMetadata {
+		lines = append(lines, fmt.Sprintf("    - %s", meta))
+	}
+	return lines
 }
 
-// stripMetadata удаляет метаданные из видеофайла используя ffmpeg
-func stripMetadata(ctx context.Context, inputFile string) error {
+// stripMetadata удаляет метаданные из видеофайла используя ffmpeg. keepLang
+// ограничивает набор сохраняемых аудио- и субтитровых дорожек по языку (см.
+// buildMapArgs в tracks.go); пустой keepLang сохраняет все дорожки, кроме
+// глав, обложек и вложений-шрифтов, которые всегда убираются.
+func stripMetadata(ctx context.Context, ffmpegPath, inputFile string, keepLang []string) error {
 	// Создаем временный файл с тем же расширением что и исходный файл
 	ext := filepath.Ext(inputFile)
 	baseName := strings.TrimSuffix(inputFile, ext)
@@ -335,18 +345,18 @@ func stripMetadata(ctx context.Context, inputFile string) error {
 	}()
 
 	// Команда ffmpeg для удаления метаданных
-	// -map_metadata -1: удаляет все метаданные
+	// -map_metadata -1: удаляет все метаданные контейнера/потоков
+	// -map_chapters -1: убирает главы
+	// -map ...: отбирает дорожки (см. buildMapArgs) — отбрасывает обложки,
+	//           вложения-шрифты и, при заданном keepLang, дорожки на других языках
 	// -c copy: копирует потоки без перекодирования (быстро)
 	// -y: перезаписывает выходной файл без запроса
 	// -loglevel error: показывает только ошибки
-	cmd := exec.CommandContext(ctx, "ffmpeg",
-		"-loglevel", "error",
-		"-i", inputFile,
-		"-map_metadata", "-1",
-		"-c", "copy",
-		"-y",
-		tmpFile,
-	)
+	args := []string{"-loglevel", "error", "-i", inputFile}
+	args = append(args, buildMapArgs(keepLang)...)
+	args = append(args, "-map_metadata", "-1", "-map_chapters", "-1", "-c", "copy", "-y", tmpFile)
+
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
 
 	// Перенаправляем вывод ffmpeg в stderr для логирования ошибок
 	cmd.Stderr = os.Stderr
@@ -0,0 +1,96 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"plugin"
+)
+
+// pluginDir возвращает директорию, из которой loadPlugins подгружает
+// сторонние .so. Пользователь кладёт туда скомпилированные плагины сам —
+// отдельного флага для пути нет, чтобы не плодить поверхность конфигурации
+// ради фичи, которой пользуются единицы.
+func pluginDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить домашнюю директорию: %w", err)
+	}
+	return filepath.Join(home, ".config", "metamover", "plugins"), nil
+}
+
+// loadPlugins подгружает все *.so из pluginDir() через plugin.Open и
+// регистрирует найденные в них обработчики и правила проверки. Каждый .so
+// должен экспортировать хотя бы одну из переменных:
+//
+//	var Handler FileHandler
+//	var VerifyRules []VerifyRule
+//
+// Отсутствие плагинов (или самой директории) не считается ошибкой —
+// подсистема опциональна.
+func loadPlugins() error {
+	dir, err := pluginDir()
+	if err != nil {
+		return err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return fmt.Errorf("ошибка поиска плагинов в %s: %w", dir, err)
+	}
+
+	for _, path := range matches {
+		if err := loadPlugin(path); err != nil {
+			log.Printf("Предупреждение: не удалось загрузить плагин %s: %v", filepath.Base(path), err)
+			continue
+		}
+		log.Printf("Плагин загружен: %s", filepath.Base(path))
+	}
+
+	return nil
+}
+
+// loadPlugin открывает один .so и регистрирует экспортированные им Handler и
+// VerifyRules. Плагин, не экспортирующий ни одного из двух символов,
+// считается ошибкой конфигурации — иначе он молча ничего бы не делал.
+func loadPlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return err
+	}
+
+	found := false
+
+	if sym, err := p.Lookup("Handler"); err == nil {
+		handler, ok := sym.(FileHandler)
+		if !ok {
+			handlerPtr, ok := sym.(*FileHandler)
+			if !ok {
+				return fmt.Errorf("символ Handler не реализует FileHandler")
+			}
+			handler = *handlerPtr
+		}
+		RegisterHandler(handler)
+		found = true
+	}
+
+	if sym, err := p.Lookup("VerifyRules"); err == nil {
+		rules, ok := sym.(*[]VerifyRule)
+		if !ok {
+			return fmt.Errorf("символ VerifyRules должен иметь тип []VerifyRule")
+		}
+		for _, rule := range *rules {
+			RegisterVerifyRule(rule)
+		}
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("плагин не экспортирует ни Handler, ни VerifyRules")
+	}
+
+	return nil
+}
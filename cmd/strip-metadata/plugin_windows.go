@@ -0,0 +1,27 @@
+//go:build windows
+
+package main
+
+import "log"
+
+// builtinWindowsPlugins — реестр плагинов для Windows, где plugin.Open не
+// поддерживается (golang.org/issue/19282). Так как динамическая загрузка
+// .so/.dll недоступна, сторонние обработчики и правила подключаются сюда
+// компилируемым кодом: форкните metamover, импортируйте свой пакет с
+// FileHandler/VerifyRule и добавьте их регистрацию в этот срез, затем
+// пересоберите бинарь под Windows.
+var builtinWindowsPlugins []func()
+
+// loadPlugins на Windows не читает файловую систему — вместо этого
+// прогоняет builtinWindowsPlugins, давая симметричную точку входа с
+// loadPlugins в plugin_unix.go, которую main вызывает не глядя на GOOS.
+func loadPlugins() error {
+	if len(builtinWindowsPlugins) == 0 {
+		log.Println("Плагины: динамическая загрузка .so недоступна на Windows, используется только встроенный реестр (builtinWindowsPlugins пуст)")
+		return nil
+	}
+	for _, register := range builtinWindowsPlugins {
+		register()
+	}
+	return nil
+}
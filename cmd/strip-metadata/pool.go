@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// perFileTimeout ограничивает время обработки одного файла, чтобы зависший
+// ffmpeg-процесс не останавливал обработку всей партии.
+const perFileTimeout = 15 * time.Minute
+
+// fileStatus описывает итог обработки одного файла воркером.
+type fileStatus int
+
+const (
+	statusProcessed fileStatus = iota
+	statusSkipped
+	statusFailed
+)
+
+// fileResult — результат обработки одного файла вместе с накопленным
+// отчётом, который логирующая горутина печатает одним куском.
+type fileResult struct {
+	file       string
+	status     fileStatus
+	err        error
+	bytesSaved int64
+	report     []string
+}
+
+// batchSummary — агрегированная статистика по всей партии файлов.
+type batchSummary struct {
+	processed  int
+	skipped    int
+	failed     int
+	bytesSaved int64
+}
+
+// merge добавляет счётчики другой сводки (используется, когда runHandledFiles
+// обрабатывает файлы через FileHandler-реестр отдельно от видео-пайплайна).
+func (s *batchSummary) merge(other batchSummary) {
+	s.processed += other.processed
+	s.skipped += other.skipped
+	s.failed += other.failed
+	s.bytesSaved += other.bytesSaved
+}
+
+// runBatch раздаёт videoFiles пулу из jobs воркеров; каждый воркер владеет
+// своим вызовом ffmpeg/ffprobe. Результаты стекаются в resultCh, откуда их
+// забирает единственная логирующая горутина — это не даёт выводу разных
+// воркеров перемежаться в терминале.
+func runBatch(ctx context.Context, scrubber Scrubber, videoFiles []string, jobs int, dryRun bool, keepLang []string) batchSummary {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	fileCh := make(chan string)
+	resultCh := make(chan fileResult)
+
+	var wg sync.WaitGroup
+	wg.Add(jobs)
+	for w := 0; w < jobs; w++ {
+		go func() {
+			defer wg.Done()
+			for file := range fileCh {
+				resultCh <- processFile(ctx, scrubber, file, dryRun, keepLang)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	go func() {
+		defer close(fileCh)
+		for _, file := range videoFiles {
+			fileCh <- file
+		}
+	}()
+
+	var summary batchSummary
+	total := len(videoFiles)
+	done := 0
+
+	for result := range resultCh {
+		done++
+		header := fmt.Sprintf("[%d/%d] %s", done, total, filepath.Base(result.file))
+		log.Println(strings.Join(append([]string{header}, result.report...), "\n"))
+
+		switch result.status {
+		case statusProcessed:
+			summary.processed++
+			summary.bytesSaved += result.bytesSaved
+		case statusSkipped:
+			summary.skipped++
+		case statusFailed:
+			summary.failed++
+		}
+	}
+
+	return summary
+}
+
+// processFile обрабатывает один файл: читает метаданные, при необходимости
+// удаляет их и проверяет результат. В режиме dry-run вызывается только
+// getMetadata — stripMetadata не трогает файл.
+func processFile(ctx context.Context, scrubber Scrubber, file string, dryRun bool, keepLang []string) fileResult {
+	fileCtx, cancel := context.WithTimeout(ctx, perFileTimeout)
+	defer cancel()
+
+	var report []string
+
+	metadata, err := scrubber.Probe(fileCtx, file)
+	if err != nil {
+		report = append(report, fmt.Sprintf("  Предупреждение: не удалось прочитать метаданные: %v", err))
+	} else {
+		report = append(report, describeMetadata(metadata)...)
+		report = append(report, describeRemovedTracks(metadata, keepLang)...)
+	}
+
+	if dryRun {
+		return fileResult{file: file, status: statusSkipped, report: report}
+	}
+
+	report = append(report, "  Удаляю метаданные...")
+	sizeBefore, _ := fileSize(file)
+
+	if err := scrubber.Strip(fileCtx, file); err != nil {
+		report = append(report, fmt.Sprintf("  Ошибка обработки: %v", err))
+		return fileResult{file: file, status: statusFailed, err: err, report: report}
+	}
+
+	verifyLines, err := scrubber.VerifyRemoved(fileCtx, file)
+	if err != nil {
+		report = append(report, fmt.Sprintf("  Предупреждение: не удалось проверить удаление метаданных: %v", err))
+	} else {
+		report = append(report, verifyLines...)
+	}
+
+	sizeAfter, _ := fileSize(file)
+	saved := sizeBefore - sizeAfter
+	if saved < 0 {
+		saved = 0
+	}
+
+	return fileResult{file: file, status: statusProcessed, bytesSaved: saved, report: report}
+}
+
+// fileSize возвращает размер файла в байтах; ошибка игнорируется вызывающей
+// стороной, так как отсутствие размера не должно прерывать обработку.
+func fileSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// formatBytes форматирует количество байт в человекочитаемый вид для
+// итоговой сводки.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
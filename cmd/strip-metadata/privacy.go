@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dateLayout — формат, принимаемый флагом --fake-date.
+const dateLayout = "2006-01-02"
+
+// defaultFakeDate используется в --privacy, если не заданы ни --fake-date,
+// ни --random-date.
+var defaultFakeDate = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
+
+// privacyOptions управляет режимом --privacy: какое значение creation_time
+// записать в контейнер и выставить через os.Chtimes на сам файл.
+type privacyOptions struct {
+	enabled bool
+	fixed   time.Time // используется, если random == false
+	random  bool
+}
+
+// resolvePrivacyOptions разбирает --privacy/--fake-date/--random-date.
+func resolvePrivacyOptions(f *cliFlags) (*privacyOptions, error) {
+	if !f.privacy {
+		return &privacyOptions{}, nil
+	}
+
+	if f.randomDate {
+		return &privacyOptions{enabled: true, random: true}, nil
+	}
+
+	if f.fakeDate == "" {
+		return &privacyOptions{enabled: true, fixed: defaultFakeDate}, nil
+	}
+
+	fixed, err := time.Parse(dateLayout, f.fakeDate)
+	if err != nil {
+		return nil, fmt.Errorf("некорректное значение --fake-date %q (ожидается формат ГГГГ-ММ-ДД): %w", f.fakeDate, err)
+	}
+	return &privacyOptions{enabled: true, fixed: fixed}, nil
+}
+
+// timestampFor возвращает временную метку, которую нужно записать в
+// конкретный файл: фиксированную дату либо новую случайную на каждый вызов.
+func (o *privacyOptions) timestampFor() time.Time {
+	if !o.random {
+		return o.fixed
+	}
+	// Случайная дата в диапазоне последних ~20 лет, чтобы не выглядеть
+	// подозрительно единообразно между файлами.
+	const twentyYears = 20 * 365 * 24 * time.Hour
+	offset := time.Duration(rand.Int63n(int64(twentyYears)))
+	return time.Now().Add(-offset).UTC().Truncate(time.Second)
+}
+
+// applyPrivacy переписывает creation_time контейнера и каждого потока на
+// timestamp, а также выставляет mtime/atime самого файла тем же значением.
+// ffmpeg сам транслирует -metadata creation_time в атомы mvhd/tkhd при
+// ремуксинге mp4/mov, поэтому отдельный бинарный редактор бокса не нужен.
+// Возвращает фактически применённый timestamp — при --random-date он
+// генерируется заново на каждый вызов, и вызывающая сторона должна сохранить
+// его для последующего аудита в VerifyRemoved.
+func applyPrivacy(ctx context.Context, ffmpegPath, ffprobePath, path string, opts *privacyOptions) (time.Time, error) {
+	if !opts.enabled {
+		return time.Time{}, nil
+	}
+
+	metadata, err := getMetadata(ffprobePath, path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("не удалось прочитать потоки для --privacy: %w", err)
+	}
+
+	ts := opts.timestampFor()
+	tsValue := ts.Format(time.RFC3339)
+
+	ext := filepath.Ext(path)
+	baseName := strings.TrimSuffix(path, ext)
+	tmpFile := baseName + ".privacy.tmp" + ext
+
+	defer func() {
+		if _, err := os.Stat(tmpFile); err == nil {
+			os.Remove(tmpFile)
+		}
+	}()
+
+	args := []string{
+		"-loglevel", "error",
+		"-i", path,
+		"-map", "0",
+		"-c", "copy",
+		"-metadata", "creation_time=" + tsValue,
+	}
+	for _, stream := range metadata.Streams {
+		args = append(args, fmt.Sprintf("-metadata:s:%d", stream.Index), "creation_time="+tsValue)
+	}
+	args = append(args, "-y", tmpFile)
+
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	cmd.Stderr = os.Stderr
+	cmd.Stdout = os.Stdout
+
+	if err := cmd.Run(); err != nil {
+		return time.Time{}, fmt.Errorf("ошибка переписывания временных меток: %w", err)
+	}
+
+	if _, err := os.Stat(tmpFile); os.IsNotExist(err) {
+		return time.Time{}, fmt.Errorf("временный файл не был создан")
+	}
+
+	if err := os.Rename(tmpFile, path); err != nil {
+		return time.Time{}, fmt.Errorf("ошибка замены файла: %w", err)
+	}
+
+	if err := os.Chtimes(path, ts, ts); err != nil {
+		return time.Time{}, fmt.Errorf("ошибка изменения mtime/atime: %w", err)
+	}
+
+	return ts, nil
+}
+
+// describeTimestampAudit проверяет, что после --privacy в контейнере не
+// осталось временных меток, отличных от записанной want — то есть что
+// оригинальная дата съёмки нигде не "протекла" (например, в потоке, не
+// охваченном -metadata:s:N из-за расхождения индексов).
+func describeTimestampAudit(metadata *metadataInfo, want time.Time) []string {
+	var leaked []string
+
+	check := func(label, value string) {
+		if value == "" {
+			return
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil || !parsed.Equal(want) {
+			leaked = append(leaked, fmt.Sprintf("%s: %s (ожидалось %s)", label, value, want.Format(time.RFC3339)))
+		}
+	}
+
+	if metadata.Format.Tags != nil {
+		check("format.creation_time", metadata.Format.Tags["creation_time"])
+	}
+	for _, stream := range metadata.Streams {
+		if stream.Tags != nil {
+			check(fmt.Sprintf("stream[%d].creation_time", stream.Index), stream.Tags["creation_time"])
+		}
+	}
+
+	if len(leaked) == 0 {
+		return []string{"  ✓ Временные метки переписаны, утечек не обнаружено"}
+	}
+
+	lines := []string{"  ⚠️  Предупреждение: обнаружены непереписанные временные метки:"}
+	for _, l := range leaked {
+		lines = append(lines, fmt.Sprintf("    - %s", l))
+	}
+	return lines
+}
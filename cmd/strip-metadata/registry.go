@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Metadata — произвольный набор тегов метаданных, не привязанный к формату
+// конкретного контейнера (в отличие от metadataInfo, заточенной под вывод
+// ffprobe). FileHandler-реализации для изображений, PDF и сторонних плагинов
+// используют именно Metadata, чтобы registry мог проверять находки общими
+// VerifyRule независимо от типа файла.
+type Metadata map[string]string
+
+// Finding — замечание, которое VerifyRule обнаружило в Metadata после Strip.
+type Finding struct {
+	Severity string // "info" или "warning"
+	Message  string
+}
+
+// FileHandler обрабатывает один тип файлов по расширению. Встроенные
+// image/pdf обработчики и сторонние Go-плагины из
+// ~/.config/metamover/plugins/*.so реализуют этот интерфейс и регистрируются
+// в общем реестре через RegisterHandler. Видео в реестре не регистрируется:
+// оно идёт отдельно, через Scrubber и runBatch, а findHandledFiles пропускает
+// видеорасширения.
+type FileHandler interface {
+	Extensions() []string
+	Strip(ctx context.Context, path string) error
+	Probe(path string) (Metadata, error)
+}
+
+// VerifyRule проверяет Metadata, оставшуюся после Strip, и сообщает о
+// находках — например, о теге, который ни один обработчик не должен был
+// оставить. Плагины могут добавлять свои правила экспортируемой переменной
+// VerifyRules (см. plugin_unix.go).
+type VerifyRule interface {
+	Check(Metadata) []Finding
+}
+
+// registry хранит обработчики (по расширению) и глобальные правила проверки.
+// Регистрация происходит из init() встроенных обработчиков и из loadPlugins
+// при старте, после чего реестр читают HandlerFor/runVerifyRules. Все
+// обращения к реестру идут под мьютексом.
+type registry struct {
+	mu       sync.Mutex
+	handlers map[string]FileHandler
+	rules    []VerifyRule
+}
+
+var globalRegistry = &registry{handlers: make(map[string]FileHandler)}
+
+// RegisterHandler регистрирует обработчик под всеми расширениями, которые он
+// объявляет в Extensions(). Более поздняя регистрация того же расширения
+// перекрывает раннюю — так плагин пользователя может заменить встроенный
+// обработчик.
+func RegisterHandler(h FileHandler) {
+	globalRegistry.mu.Lock()
+	defer globalRegistry.mu.Unlock()
+	for _, ext := range h.Extensions() {
+		globalRegistry.handlers[strings.ToLower(ext)] = h
+	}
+}
+
+// RegisterVerifyRule добавляет правило проверки, применяемое ко всем файлам
+// независимо от того, каким обработчиком они были стрипнуты.
+func RegisterVerifyRule(r VerifyRule) {
+	globalRegistry.mu.Lock()
+	defer globalRegistry.mu.Unlock()
+	globalRegistry.rules = append(globalRegistry.rules, r)
+}
+
+// HandlerFor возвращает обработчик, зарегистрированный для расширения файла
+// (с точкой, например ".jpg").
+func HandlerFor(ext string) (FileHandler, bool) {
+	globalRegistry.mu.Lock()
+	defer globalRegistry.mu.Unlock()
+	h, ok := globalRegistry.handlers[strings.ToLower(ext)]
+	return h, ok
+}
+
+// runVerifyRules прогоняет все зарегистрированные правила по metadata и
+// форматирует находки в строки отчёта, в том же стиле что describeMetadata.
+func runVerifyRules(metadata Metadata) []string {
+	globalRegistry.mu.Lock()
+	rules := append([]VerifyRule(nil), globalRegistry.rules...)
+	globalRegistry.mu.Unlock()
+
+	var lines []string
+	for _, rule := range rules {
+		for _, finding := range rule.Check(metadata) {
+			lines = append(lines, fmt.Sprintf("  [%s] %s", finding.Severity, finding.Message))
+		}
+	}
+
+	if len(lines) == 0 {
+		return []string{"  ✓ Метаданные успешно удалены"}
+	}
+	return lines
+}
+
+// nonEmptyMetadataRule — встроенное правило по умолчанию: любое непустое
+// значение, оставшееся в Metadata после Strip, считается потенциальной
+// утечкой и попадает в отчёт.
+type nonEmptyMetadataRule struct{}
+
+func init() {
+	RegisterVerifyRule(nonEmptyMetadataRule{})
+}
+
+func (nonEmptyMetadataRule) Check(m Metadata) []Finding {
+	var findings []Finding
+	for key, value := range m {
+		if value != "" {
+			findings = append(findings, Finding{Severity: "warning", Message: fmt.Sprintf("%s: %s", key, value)})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Scrubber абстрагирует движок, которым metamover вычищает метаданные из
+// видеофайла. Реализаций две: execScrubber (системные ffmpeg/ffprobe через
+// os/exec) и wasmScrubber (те же бинари, скомпилированные в WebAssembly и
+// исполняемые через wazero, без системной установки). Бэкенд выбирается
+// флагом --backend, но buildScrubber создаёт только execScrubber: на
+// --backend=wasm он возвращает ошибку, и wasmScrubber из CLI не используется.
+type Scrubber interface {
+	// Probe читает метаданные контейнера и потоков файла.
+	Probe(ctx context.Context, path string) (*metadataInfo, error)
+	// Strip удаляет метаданные из файла на месте.
+	Strip(ctx context.Context, path string) error
+	// VerifyRemoved перечитывает файл и возвращает отчёт о том, что из
+	// метаданных осталось после Strip (см. describeMetadata/describeRemaining).
+	VerifyRemoved(ctx context.Context, path string) ([]string, error)
+}
+
+// buildScrubber выбирает и инициализирует Scrubber-бэкенд согласно
+// --backend. Для exec предварительно находит (и при необходимости скачивает)
+// системные ffmpeg/ffprobe; wasm пока отклоняется явной ошибкой (см. ниже).
+// keepLang передаётся дальше в бэкенд и управляет тем, какие аудио/субтитровые
+// дорожки сохраняются при Strip (см. buildMapArgs в tracks.go).
+func buildScrubber(ctx context.Context, backend string, cfg *Config, keepLang []string, privacy *privacyOptions) (Scrubber, error) {
+	switch backend {
+	case "", "exec":
+		tools, err := checkFFmpeg(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newExecScrubber(tools, keepLang, privacy), nil
+	case "wasm":
+		// wasmScrubber скачивает ffmpeg.wasm/ffprobe.wasm с релизов
+		// github.com/Scryptor/metamover-wasm, которых пока не существует —
+		// бэкенд не готов к использованию. Отклоняем явно здесь, а не даём
+		// пользователю напороться на невнятную ошибку загрузки внутри
+		// newWasmScrubber при первом файле.
+		return nil, fmt.Errorf("--backend=wasm пока экспериментальный и недоступен: сборки ffmpeg.wasm/ffprobe.wasm ещё не опубликованы; используйте --backend=exec")
+	default:
+		return nil, errUnknownBackend(backend)
+	}
+}
+
+type errUnknownBackend string
+
+func (e errUnknownBackend) Error() string {
+	return "неизвестный бэкенд: " + string(e) + " (допустимые значения: exec, wasm)"
+}
+
+// execScrubber — бэкенд на основе системных бинарей ffmpeg/ffprobe,
+// найденных checkFFmpeg.
+type execScrubber struct {
+	tools    *resolvedTools
+	keepLang []string
+	privacy  *privacyOptions
+
+	// appliedTimestamps запоминает, какой timestamp applyPrivacy записал в
+	// каждый файл (важно при --random-date, где каждый вызов генерирует новое
+	// значение), чтобы VerifyRemoved сверялся с тем же значением, а не
+	// сгенерировал своё. Воркеры обращаются к карте параллельно, отсюда мьютекс.
+	mu                sync.Mutex
+	appliedTimestamps map[string]time.Time
+}
+
+func newExecScrubber(tools *resolvedTools, keepLang []string, privacy *privacyOptions) *execScrubber {
+	return &execScrubber{
+		tools:             tools,
+		keepLang:          keepLang,
+		privacy:           privacy,
+		appliedTimestamps: make(map[string]time.Time),
+	}
+}
+
+func (s *execScrubber) Probe(ctx context.Context, path string) (*metadataInfo, error) {
+	return getMetadata(s.tools.ffprobe, path)
+}
+
+func (s *execScrubber) Strip(ctx context.Context, path string) error {
+	// Извлекаем субтитры в сайдкары, только если задан --keep-lang: только
+	// тогда buildMapArgs(s.keepLang) может выкинуть часть субтитровых дорожек
+	// из контейнера (см. tracks.go) — без --keep-lang все субтитры остаются
+	// встроенными, и сайдкары рядом с ними были бы лишним мусором. При
+	// заданном --keep-lang извлекаются все текстовые дорожки, в том числе
+	// сохраняемые. Ошибка извлечения не должна останавливать сам strip.
+	if len(s.keepLang) > 0 {
+		if metadata, err := getMetadata(s.tools.ffprobe, path); err == nil {
+			if _, err := extractSubtitles(ctx, s.tools.ffmpeg, path, metadata); err != nil {
+				log.Printf("Предупреждение: не удалось извлечь субтитры из %s: %v", filepath.Base(path), err)
+			}
+		}
+	}
+
+	if err := stripMetadata(ctx, s.tools.ffmpeg, path, s.keepLang); err != nil {
+		return err
+	}
+
+	if s.privacy != nil && s.privacy.enabled {
+		ts, err := applyPrivacy(ctx, s.tools.ffmpeg, s.tools.ffprobe, path, s.privacy)
+		if err != nil {
+			return fmt.Errorf("ошибка режима --privacy: %w", err)
+		}
+		s.mu.Lock()
+		s.appliedTimestamps[path] = ts
+		s.mu.Unlock()
+	}
+
+	return nil
+}
+
+func (s *execScrubber) VerifyRemoved(ctx context.Context, path string) ([]string, error) {
+	privacyEnabled := s.privacy != nil && s.privacy.enabled
+	lines, err := verifyMetadataRemoved(s.tools.ffprobe, path, privacyEnabled)
+	if err != nil {
+		return nil, err
+	}
+
+	if privacyEnabled {
+		s.mu.Lock()
+		want, ok := s.appliedTimestamps[path]
+		s.mu.Unlock()
+
+		if ok {
+			if metadata, err := getMetadata(s.tools.ffprobe, path); err == nil {
+				lines = append(lines, describeTimestampAudit(metadata, want)...)
+			}
+		}
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/asticode/go-astisub"
+)
+
+// parseKeepLang разбирает значение флага --keep-lang ("en,ru") в список
+// ISO-639 кодов языков. Пустая строка означает "сохранить все дорожки".
+func parseKeepLang(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	var langs []string
+	for _, lang := range strings.Split(value, ",") {
+		lang = strings.TrimSpace(lang)
+		if lang != "" {
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// buildMapArgs строит аргументы -map для ffmpeg. Без keepLang сохраняются все
+// видео/аудио/субтитровые потоки, но не обложки и не вложения (шрифты). При
+// заданном keepLang аудио и субтитры дополнительно фильтруются по языку;
+// "?" на конце спецификатора не даёт ffmpeg упасть, если подходящих дорожек нет.
+func buildMapArgs(keepLang []string) []string {
+	if len(keepLang) == 0 {
+		return []string{
+			"-map", "0",
+			"-map", "-0:t",
+			"-map", "-0:v:m:attached_pic",
+		}
+	}
+
+	args := []string{
+		"-map", "0:v",
+		"-map", "-0:v:m:attached_pic",
+	}
+	for _, lang := range keepLang {
+		args = append(args, "-map", fmt.Sprintf("0:a:m:language:%s?", lang))
+		args = append(args, "-map", fmt.Sprintf("0:s:m:language:%s?", lang))
+	}
+	return args
+}
+
+// describeRemovedTracks перечисляет дорожки и главы, которые stripMetadata
+// уберёт из файла согласно keepLang, чтобы пользователь увидел это в отчёте
+// до запуска ffmpeg (см. displayMetadata/describeMetadata).
+func describeRemovedTracks(metadata *metadataInfo, keepLang []string) []string {
+	if metadata == nil {
+		return nil
+	}
+
+	keep := make(map[string]bool, len(keepLang))
+	for _, lang := range keepLang {
+		keep[lang] = true
+	}
+
+	var removed []string
+	for _, stream := range metadata.Streams {
+		lang := stream.Tags["language"]
+
+		switch stream.CodecType {
+		case "audio", "subtitle":
+			if len(keep) > 0 && !keep[lang] {
+				removed = append(removed, fmt.Sprintf("stream[%d] (%s, язык: %s)", stream.Index, stream.CodecType, langOrUnknown(lang)))
+			}
+		case "attachment":
+			removed = append(removed, fmt.Sprintf("stream[%d] (вложение: %s)", stream.Index, stream.Tags["filename"]))
+		case "video":
+			if stream.Tags["attached_pic"] != "" || isAttachedPicDisposition(stream) {
+				removed = append(removed, fmt.Sprintf("stream[%d] (обложка/attached_pic)", stream.Index))
+			}
+		}
+	}
+
+	// Главы всегда убираются через -map_chapters -1, вне зависимости от keepLang.
+	for _, chapter := range metadata.Chapters {
+		removed = append(removed, fmt.Sprintf("chapter[%d] (%s)", chapter.ID, chapterTitle(chapter)))
+	}
+
+	if len(removed) == 0 {
+		return nil
+	}
+
+	lines := []string{"  Будут удалены дорожки:"}
+	for _, r := range removed {
+		lines = append(lines, fmt.Sprintf("    - %s", r))
+	}
+	return lines
+}
+
+// isAttachedPicDisposition — запасной способ распознать обложку, если
+// ffprobe не прокинул disposition в Tags. Пока не используется ffprobe без
+// -show_entries disposition, оставлен как явная точка расширения.
+func isAttachedPicDisposition(stream streamInfo) bool {
+	_ = stream
+	return false
+}
+
+// langOrUnknown возвращает "без языка", если тег language не задан.
+func langOrUnknown(lang string) string {
+	if lang == "" {
+		return "без языка"
+	}
+	return lang
+}
+
+// chapterTitle возвращает заголовок главы для отчёта, если он есть в тегах,
+// иначе отмечает главу как безымянную.
+func chapterTitle(chapter chapterInfo) string {
+	if title := chapter.Tags["title"]; title != "" {
+		return title
+	}
+	return "без названия"
+}
+
+// subtitleSidecarExt сопоставляет кодек субтитров с расширением текстового
+// сайдкара. Пустая строка означает, что дорожка не текстовая (например,
+// PGS/DVD-субтитры) и извлечение в .srt/.ass не поддерживается.
+func subtitleSidecarExt(codecName string) string {
+	switch codecName {
+	case "subrip", "srt":
+		return "srt"
+	case "ass", "ssa":
+		return "ass"
+	default:
+		return ""
+	}
+}
+
+// extractSubtitles сохраняет каждую текстовую дорожку субтитров в отдельный
+// сайдкар-файл рядом с видео (ffmpeg -map 0:N -c copy), прежде чем
+// stripMetadata уберёт из контейнера дорожки на языках не из keepLang.
+// Извлекаются все текстовые дорожки, а не только удаляемые. Сайдкары нормализуются
+// через go-astisub, чтобы привести тайминги/кодировку к каноническому виду.
+func extractSubtitles(ctx context.Context, ffmpegPath, inputFile string, metadata *metadataInfo) ([]string, error) {
+	var sidecars []string
+
+	for _, stream := range metadata.Streams {
+		if stream.CodecType != "subtitle" {
+			continue
+		}
+
+		ext := subtitleSidecarExt(stream.CodecName)
+		if ext == "" {
+			continue
+		}
+
+		sidecar := subtitleSidecarPath(inputFile, stream.Index, stream.Tags["language"], ext)
+
+		cmd := exec.CommandContext(ctx, ffmpegPath,
+			"-loglevel", "error",
+			"-y",
+			"-i", inputFile,
+			"-map", fmt.Sprintf("0:%d", stream.Index),
+			"-c", "copy",
+			sidecar,
+		)
+		if err := cmd.Run(); err != nil {
+			log.Printf("Предупреждение: не удалось извлечь субтитры (поток %d): %v", stream.Index, err)
+			continue
+		}
+
+		if err := normalizeSubtitle(sidecar); err != nil {
+			log.Printf("Предупреждение: не удалось нормализовать субтитры %s: %v", sidecar, err)
+		}
+
+		sidecars = append(sidecars, sidecar)
+	}
+
+	return sidecars, nil
+}
+
+// subtitleSidecarPath формирует имя сайдкар-файла вида "video.en.srt"; если
+// язык не указан, используется индекс потока: "video.2.srt".
+func subtitleSidecarPath(inputFile string, index int, lang, ext string) string {
+	base := strings.TrimSuffix(inputFile, filepath.Ext(inputFile))
+	if lang == "" {
+		return fmt.Sprintf("%s.%d.%s", base, index, ext)
+	}
+	return fmt.Sprintf("%s.%s.%s", base, lang, ext)
+}
+
+// normalizeSubtitle перечитывает и пересохраняет сайдкар через go-astisub,
+// приводя тайминги и кодировку к единому виду.
+func normalizeSubtitle(path string) error {
+	subs, err := astisub.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	return subs.Write(path)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// wasmModuleURLs указывает, откуда getWasmRuntime скачивает wasm-сборки
+// ffmpeg/ffprobe, если их нет в кэше. Сами .wasm-файлы не хранятся в
+// репозитории. buildScrubber на --backend=wasm возвращает ошибку и
+// newWasmScrubber не вызывает, так что из CLI эта загрузка не запускается.
+var wasmModuleURLs = map[string]string{
+	"ffmpeg":  "https://github.com/Scryptor/metamover-wasm/releases/latest/download/ffmpeg.wasm",
+	"ffprobe": "https://github.com/Scryptor/metamover-wasm/releases/latest/download/ffprobe.wasm",
+}
+
+// wasmRuntime — общий на весь процесс рантайм wazero со скомпилированными
+// модулями ffmpeg/ffprobe. Инициализируется один раз и переиспользуется для
+// каждого файла, чтобы не платить за компиляцию wasm на каждый вызов.
+type wasmRuntime struct {
+	runtime wazero.Runtime
+	ffmpeg  wazero.CompiledModule
+	ffprobe wazero.CompiledModule
+}
+
+var (
+	sharedWasmRuntime     *wasmRuntime
+	sharedWasmRuntimeOnce sync.Once
+	sharedWasmRuntimeErr  error
+)
+
+// getWasmRuntime возвращает общий wasmRuntime, инициализируя его при первом
+// обращении: скачивает недостающие модули в кэш, поднимает wazero.Runtime с
+// диск-кэшем компиляции и компилирует оба модуля.
+func getWasmRuntime(ctx context.Context) (*wasmRuntime, error) {
+	sharedWasmRuntimeOnce.Do(func() {
+		sharedWasmRuntime, sharedWasmRuntimeErr = newWasmRuntime(ctx)
+	})
+	return sharedWasmRuntime, sharedWasmRuntimeErr
+}
+
+func newWasmRuntime(ctx context.Context) (*wasmRuntime, error) {
+	moduleDir, err := wasmModuleDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(moduleDir, 0o755); err != nil {
+		return nil, fmt.Errorf("не удалось создать директорию wasm-модулей %s: %w", moduleDir, err)
+	}
+
+	cacheDir, err := wazeroCompilationCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("не удалось создать кэш компиляции wazero %s: %w", cacheDir, err)
+	}
+
+	compilationCache, err := wazero.NewCompilationCacheWithDir(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации кэша компиляции wazero: %w", err)
+	}
+
+	rt := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCompilationCache(compilationCache))
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, rt); err != nil {
+		return nil, fmt.Errorf("ошибка инициализации WASI: %w", err)
+	}
+
+	ffmpegModule, err := compileWasmModule(ctx, rt, moduleDir, "ffmpeg")
+	if err != nil {
+		return nil, err
+	}
+
+	ffprobeModule, err := compileWasmModule(ctx, rt, moduleDir, "ffprobe")
+	if err != nil {
+		return nil, err
+	}
+
+	return &wasmRuntime{runtime: rt, ffmpeg: ffmpegModule, ffprobe: ffprobeModule}, nil
+}
+
+// compileWasmModule гарантирует, что модуль name скачан в moduleDir, и
+// компилирует его в рамках rt.
+func compileWasmModule(ctx context.Context, rt wazero.Runtime, moduleDir, name string) (wazero.CompiledModule, error) {
+	path := filepath.Join(moduleDir, name+".wasm")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := downloadFile(ctx, wasmModuleURLs[name], path); err != nil {
+			return nil, fmt.Errorf("ошибка загрузки %s.wasm: %w", name, err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения %s.wasm: %w", name, err)
+	}
+
+	module, err := rt.CompileModule(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка компиляции %s.wasm: %w", name, err)
+	}
+	return module, nil
+}
+
+func wasmModuleDir() (string, error) {
+	dir, err := cacheBinDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dir), "wasm"), nil
+}
+
+func wazeroCompilationCacheDir() (string, error) {
+	dir, err := cacheBinDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dir), "wazero-cache"), nil
+}
+
+// wasmScrubber — Scrubber-бэкенд на встроенных wasm-сборках ffmpeg/ffprobe,
+// исполняемых через wazero. Не требует системной установки ffmpeg.
+type wasmScrubber struct {
+	rt       *wasmRuntime
+	keepLang []string
+}
+
+func newWasmScrubber(ctx context.Context, keepLang []string) (*wasmScrubber, error) {
+	rt, err := getWasmRuntime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &wasmScrubber{rt: rt, keepLang: keepLang}, nil
+}
+
+func (s *wasmScrubber) Probe(ctx context.Context, path string) (*metadataInfo, error) {
+	output, err := s.run(ctx, s.rt.ffprobe, path, []string{
+		"ffprobe", "-v", "quiet", "-print_format", "json", "-show_format", "-show_streams", "-show_chapters", filepath.Base(path),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выполнения ffprobe.wasm: %w", err)
+	}
+
+	var info metadataInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга метаданных: %w", err)
+	}
+	return &info, nil
+}
+
+// Strip применяет тот же план -map, что и execScrubber (см. buildMapArgs),
+// но не извлекает субтитры в сайдкары — запуск дополнительного вызова
+// ffmpeg.wasm на гостевой ФС того же файла того не стоит; для извлечения
+// субтитров используйте --backend=exec.
+func (s *wasmScrubber) Strip(ctx context.Context, path string) error {
+	ext := filepath.Ext(path)
+	baseName := strings.TrimSuffix(path, ext)
+	tmpFile := baseName + ".tmp" + ext
+
+	args := []string{"ffmpeg", "-loglevel", "error", "-i", filepath.Base(path)}
+	args = append(args, buildMapArgs(s.keepLang)...)
+	args = append(args, "-map_metadata", "-1", "-map_chapters", "-1", "-c", "copy", "-y", filepath.Base(tmpFile))
+	if _, err := s.run(ctx, s.rt.ffmpeg, path, args); err != nil {
+		return fmt.Errorf("ошибка выполнения ffmpeg.wasm: %w", err)
+	}
+
+	if _, err := os.Stat(tmpFile); os.IsNotExist(err) {
+		return fmt.Errorf("временный файл не был создан")
+	}
+	return os.Rename(tmpFile, path)
+}
+
+func (s *wasmScrubber) VerifyRemoved(ctx context.Context, path string) ([]string, error) {
+	metadata, err := s.Probe(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return describeRemaining(metadata, false), nil
+}
+
+// wasmInstanceSeq нумерует каждый вызов InstantiateModule, чтобы дать ему
+// уникальное имя в общем рантайме (см. run) — без этого конкурентные вызовы
+// из разных воркеров runBatch регистрировались бы под одним и тем же именем
+// модуля и падали с конфликтом в пространстве имён рантайма.
+var wasmInstanceSeq uint64
+
+// run запускает compiled модуль module с аргументами args, монтируя
+// директорию файла path в гостевую ФС, чтобы wasm-гость видел реальные пути.
+// module общий на процесс и вызывается параллельно из пула воркеров, поэтому
+// каждому инстансу присваивается уникальное имя (WithName) — иначе
+// InstantiateModule регистрирует его под именем модуля по умолчанию, и
+// одновременные вызовы для разных файлов конфликтуют в общем wazero.Runtime.
+func (s *wasmScrubber) run(ctx context.Context, module wazero.CompiledModule, path string, args []string) ([]byte, error) {
+	var stdout bytes.Buffer
+
+	instanceName := fmt.Sprintf("%s-%d", module.Name(), atomic.AddUint64(&wasmInstanceSeq, 1))
+
+	cfg := wazero.NewModuleConfig().
+		WithName(instanceName).
+		WithArgs(args...).
+		WithStdout(&stdout).
+		WithStderr(os.Stderr).
+		WithFSConfig(wazero.NewFSConfig().WithDirMount(filepath.Dir(path), "/"))
+
+	instance, err := s.rt.runtime.InstantiateModule(ctx, module, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer instance.Close(ctx)
+
+	return stdout.Bytes(), nil
+}